@@ -4,23 +4,64 @@
 package airtable
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/ratelimit"
 )
 
-var limiter = ratelimit.New(5) // per second
+// RateLimiter paces outgoing requests. Take blocks until the caller
+// may proceed, returning the time at which it was granted. It's
+// satisfied by go.uber.org/ratelimit.Limiter; a caller who'd rather
+// use golang.org/x/time/rate can adapt it with a small wrapper around
+// rate.Limiter.Wait.
+type RateLimiter interface {
+	Take() time.Time
+}
+
+// takeContext blocks until limiter grants a slot, but gives up early if
+// ctx is done first. Limiter.Take() itself can't be interrupted, so the
+// wait runs in its own goroutine; on cancellation that goroutine is left
+// to finish on its own time rather than leak a blocked caller.
+func takeContext(ctx context.Context, limiter RateLimiter) error {
+	done := make(chan struct{})
+	go func() {
+		limiter.Take()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
 const (
-	defaultRootURL = "https://api.airtable.com"
-	defaultVersion = "v0"
+	defaultRootURL    = "https://api.airtable.com"
+	defaultVersion    = "v0"
+	defaultRateLimit  = 5 // queries per second
+	defaultMaxRetries = 3
+
+	// unsetMaxRetries marks MaxRetries as not configured, distinct
+	// from an explicit 0 ("never retry"). NewClient starts every
+	// Client here so WithRetry(0) sticks instead of being defaulted
+	// away.
+	unsetMaxRetries = -1
 )
 
 // Client represents an interface to communicate with the Airtable API
@@ -30,6 +71,95 @@ type Client struct {
 	Version    string
 	RootURL    string
 	HTTPClient *http.Client
+
+	// Limiter paces requests made by this client. If nil, a default
+	// limiter is created lazily from RateLimit.
+	Limiter RateLimiter
+	// RateLimit is the queries-per-second used to build the default
+	// Limiter when one isn't supplied directly. Defaults to 5.
+	RateLimit int
+	// MaxRetries is how many times a request is retried after a 429
+	// or 5xx response before the error is returned to the caller.
+	// Defaults to 3. A Client built with NewClient treats a negative
+	// value as "not configured"; a Client built as a struct literal
+	// treats the Go zero value (0) as "never retry," since there's no
+	// way to tell an unset int apart from an explicit zero.
+	MaxRetries int
+	// NoLimit disables rate limiting for this client entirely. The
+	// AIRTABLE_NO_LIMIT environment variable does the same thing for
+	// clients that don't set this explicitly.
+	NoLimit bool
+
+	// UserAgent, when set, is sent as the User-Agent header on every
+	// request.
+	UserAgent string
+
+	decoders map[reflect.Type]FieldDecoder
+}
+
+func (c *Client) noLimit() bool {
+	return c.NoLimit || os.Getenv("AIRTABLE_NO_LIMIT") != ""
+}
+
+// Option configures a Client constructed with NewClient.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to make requests. Defaults
+// to http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.HTTPClient = hc }
+}
+
+// WithVersion sets the Airtable API version segment in the request
+// URL. Defaults to "v0".
+func WithVersion(version string) Option {
+	return func(c *Client) { c.Version = version }
+}
+
+// WithRootURL overrides the Airtable API root, mainly useful for
+// pointing a client at a test server. Defaults to
+// "https://api.airtable.com".
+func WithRootURL(rootURL string) Option {
+	return func(c *Client) { c.RootURL = rootURL }
+}
+
+// WithRateLimit sets the queries-per-second used to build the
+// client's default Limiter. Defaults to 5.
+func WithRateLimit(qps int) Option {
+	return func(c *Client) { c.RateLimit = qps }
+}
+
+// WithRetry sets how many times a request is retried after a 429 or
+// 5xx response before the error is returned to the caller. Defaults
+// to 3.
+func WithRetry(maxRetries int) Option {
+	return func(c *Client) { c.MaxRetries = maxRetries }
+}
+
+// WithUserAgent sets the User-Agent header sent on every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.UserAgent = userAgent }
+}
+
+// WithNoLimit disables rate limiting for this client entirely,
+// equivalent to setting the AIRTABLE_NO_LIMIT environment variable.
+func WithNoLimit() Option {
+	return func(c *Client) { c.NoLimit = true }
+}
+
+// NewClient returns a Client for the base identified by baseID,
+// authenticating with apiKey, customized by any supplied Options.
+func NewClient(apiKey, baseID string, opts ...Option) *Client {
+	c := &Client{
+		APIKey:     apiKey,
+		BaseID:     baseID,
+		HTTPClient: http.DefaultClient,
+		MaxRetries: unsetMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // ErrClientRequestError is returned when the client runs into
@@ -58,6 +188,15 @@ func (c *Client) checkSetup() {
 	if c.RootURL == "" {
 		c.RootURL = defaultRootURL
 	}
+	if c.RateLimit == 0 {
+		c.RateLimit = defaultRateLimit
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.Limiter == nil {
+		c.Limiter = ratelimit.New(c.RateLimit)
+	}
 }
 
 func (c *Client) makeURL(resource string, options QueryEncoder) string {
@@ -82,9 +221,51 @@ func checkErrorResponse(b []byte) error {
 	if reqerr.Error.Type != "" {
 		return ErrClientRequestError{reqerr.Error.Message}
 	}
+
+	var batcherr batchErrorResponse
+	if jsonerr := json.Unmarshal(b, &batcherr); jsonerr != nil {
+		return jsonerr
+	}
+	var errs []ErrClientRequestError
+	for _, rec := range batcherr.Records {
+		if rec.Error != nil {
+			errs = append(errs, ErrClientRequestError{rec.Error.Message})
+		}
+	}
+	if len(errs) > 0 {
+		return ErrBatchRequestError{errs}
+	}
+
 	return nil
 }
 
+// batchErrorResponse mirrors the per-record shape Airtable uses when
+// one or more records in a batch write fails: the response carries a
+// "records" array where the failing entries hold an "error" object
+// instead of "fields".
+type batchErrorResponse struct {
+	Records []struct {
+		Error *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"records"`
+}
+
+// ErrBatchRequestError is returned when a batch write partially fails;
+// it collects the per-record errors Airtable reported.
+type ErrBatchRequestError struct {
+	Errors []ErrClientRequestError
+}
+
+func (e ErrBatchRequestError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("airtable: %d record(s) in batch failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
 // QueryEncoder encodes options to a query string
 type QueryEncoder interface {
 	Encode() string
@@ -99,8 +280,15 @@ type GetResponse struct {
 
 // Get returns information about a resource
 func (r *Resource) Get(id string, options QueryEncoder) (*GetResponse, error) {
+	return r.GetContext(context.Background(), id, options)
+}
+
+// GetContext is like Get but carries ctx through the underlying HTTP
+// request, so callers can cancel a slow lookup or bound it with a
+// deadline.
+func (r *Resource) GetContext(ctx context.Context, id string, options QueryEncoder) (*GetResponse, error) {
 	fullid := path.Join(r.name, id)
-	bytes, err := r.client.RequestBytes("GET", fullid, options)
+	bytes, err := r.client.RequestBytesContext(ctx, "GET", fullid, options)
 	if err != nil {
 		return nil, err
 	}
@@ -111,171 +299,229 @@ func (r *Resource) Get(id string, options QueryEncoder) (*GetResponse, error) {
 		return nil, err
 	}
 
-	// record comes in as an `interface {}` so let's get a pointer for
-	// it and unwrap until we can get a value for the underlying struct
-	refPtrToStruct := reflect.ValueOf(&r.record).Elem()
-	structAsInterface := refPtrToStruct.Interface()
-	refStruct := reflect.ValueOf(structAsInterface).Elem()
-	refStructType := refStruct.Type()
+	if err := decodeFields(r.client, resp.Fields, r.record); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Resource ...
+type Resource struct {
+	name   string
+	client *Client
+	record interface{}
+}
+
+// NewResource returns a new resource manipulator
+func (c *Client) NewResource(name string, record interface{}) Resource {
+	// TODO: panic early if record is not a pointer
+	return Resource{name, c, record}
+}
 
-	for i := 0; i < refStruct.NumField(); i++ {
-		f := refStruct.Field(i)
-		fType := refStructType.Field(i)
+// maxBatchSize is the most records Airtable allows in a single write
+// request.
+const maxBatchSize = 10
+
+// writeRecord is the per-record shape Airtable expects (and returns)
+// for create/update requests.
+type writeRecord struct {
+	ID     string      `json:"id,omitempty"`
+	Fields interface{} `json:"fields"`
+}
+
+// batchPayload is the request body for create/update endpoints, which
+// always operate on a batch of records even when there's only one.
+type batchPayload struct {
+	Records  []writeRecord `json:"records"`
+	Typecast bool          `json:"typecast,omitempty"`
+}
+
+// encodeFields is the inverse of decodeFields: it walks record (a
+// struct or pointer to one) and builds the map Airtable expects under
+// "fields", honoring the same `from:"..."` tag convention decodeFields
+// uses to read it back. When omitZero is true, fields holding their
+// Go zero value are left out instead of being sent as an explicit
+// clear, so a PATCH only touches the fields the caller actually set.
+func encodeFields(record interface{}, omitZero bool) map[string]interface{} {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	t := v.Type()
+
+	fields := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fType := t.Field(i)
+		fVal := v.Field(i)
+		if omitZero && fVal.IsZero() {
+			continue
+		}
 
 		key := fType.Name
 		if from, ok := fType.Tag.Lookup("from"); ok {
 			key = from
 		}
+		fields[key] = fVal.Interface()
+	}
+	return fields
+}
 
-		if v := resp.Fields[key]; v != nil {
-			switch f.Kind() {
-			case reflect.Struct:
-				handleStruct(key, &f, &v)
-			case reflect.Bool:
-				handleBool(key, &f, &v)
-			case reflect.Int:
-				handleInt(key, &f, &v)
-			case reflect.Float64:
-				handleFloat(key, &f, &v)
-			case reflect.String:
-				handleString(key, &f, &v)
-			case reflect.Slice:
-				handleSlice(key, &f, &v)
-			case reflect.Interface:
-				handleInterface(key, &f, &v)
-			default:
-				panic(fmt.Sprintf("UNHANDLED CASE: %s of kind %s", key, f.Kind()))
-			}
+// chunkRecords splits records into groups of at most maxBatchSize, the
+// most Airtable allows per write request.
+func chunkRecords(records []interface{}) [][]interface{} {
+	var chunks [][]interface{}
+	for len(records) > 0 {
+		n := maxBatchSize
+		if n > len(records) {
+			n = len(records)
 		}
+		chunks = append(chunks, records[:n])
+		records = records[n:]
 	}
-	return &resp, nil
+	return chunks
 }
 
-func handleString(key string, f *reflect.Value, v *interface{}) {
-	str, ok := (*v).(string)
-	if !ok {
-		panic(fmt.Sprintf("PARSE ERROR: could not parse column '%s' as string", key))
-	}
-	f.SetString(str)
-}
-func handleInt(key string, f *reflect.Value, v *interface{}) {
-	// JavaScript/JSON doesn't have ints, only float64s
-	n, ok := (*v).(float64)
-	if !ok {
-		panic(fmt.Sprintf("PARSE ERROR: could not parse column '%s' as int", key))
-	}
-	f.SetInt(int64(n))
-}
-func handleFloat(key string, f *reflect.Value, v *interface{}) {
-	// JavaScript/JSON doesn't have ints, only float64s
-	n, ok := (*v).(float64)
-	if !ok {
-		panic(fmt.Sprintf("PARSE ERROR: could not parse column '%s' as int", key))
-	}
-	f.SetFloat(n)
-}
-func handleSlice(key string, f *reflect.Value, v *interface{}) {
-	s, ok := (*v).([]interface{})
-	if !ok {
-		panic(fmt.Sprintf("PARSE ERROR: could not parse column '%s' as slice", key))
-	}
-
-	dst := reflect.MakeSlice(f.Type(), len(s), cap(s))
-
-	for i, v := range s {
-		elem := dst.Index(i)
-		switch elem.Kind() {
-		case reflect.Struct:
-			handleStruct(key, &elem, &v)
-		case reflect.Bool:
-			handleBool(key, &elem, &v)
-		case reflect.Int:
-			handleInt(key, &elem, &v)
-		case reflect.Float64:
-			handleFloat(key, &elem, &v)
-		case reflect.String:
-			handleString(key, &elem, &v)
-		case reflect.Slice:
-			handleSlice(key, &elem, &v)
-		default:
-			panic(fmt.Sprintf("UNHANDLED CASE: %s of kind %s", key, elem.Kind()))
-		}
+// Create creates a new record from record, marshaling its fields back
+// to Airtable using the same `from:"..."` tag convention Get uses to
+// decode them.
+func (r *Resource) Create(record interface{}) (*GetResponse, error) {
+	return r.CreateContext(context.Background(), record)
+}
 
+// CreateContext is like Create but carries ctx through to the
+// underlying HTTP request.
+func (r *Resource) CreateContext(ctx context.Context, record interface{}) (*GetResponse, error) {
+	resps, err := r.CreateBatchContext(ctx, []interface{}{record}, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(resps) == 0 {
+		return nil, ErrClientRequestError{"airtable: create response contained no records"}
 	}
-	f.Set(dst)
+	return &resps[0], nil
 }
-func handleStruct(key string, s *reflect.Value, v *interface{}) {
 
-	maybeParse := s.Addr().MethodByName("SelfParse")
+// CreateBatch creates one or more records, splitting them across
+// multiple requests of at most 10 records as the Airtable API
+// requires. When typecast is true, Airtable will attempt to convert
+// string values into the field's native type.
+func (r *Resource) CreateBatch(records []interface{}, typecast bool) ([]GetResponse, error) {
+	return r.CreateBatchContext(context.Background(), records, typecast)
+}
 
-	if maybeParse.Kind() == reflect.Func {
-		args := []reflect.Value{reflect.ValueOf(v)}
-		maybeParse.Call(args)
-		return
-	}
+// CreateBatchContext is like CreateBatch but carries ctx through to
+// the underlying HTTP requests.
+func (r *Resource) CreateBatchContext(ctx context.Context, records []interface{}, typecast bool) ([]GetResponse, error) {
+	var out []GetResponse
+	for _, chunk := range chunkRecords(records) {
+		payload := batchPayload{Typecast: typecast}
+		for _, rec := range chunk {
+			payload.Records = append(payload.Records, writeRecord{Fields: encodeFields(rec, false)})
+		}
 
-	m, ok := (*v).(map[string]interface{})
-	if !ok {
-		panic(fmt.Sprintf("PARSE ERROR: could not parse column '%s' as struct", key))
-	}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
 
-	sType := s.Type()
-	for i := 0; i < sType.NumField(); i++ {
-		f := s.Field(i)
-		fType := sType.Field(i)
-		key := fType.Name
-		if from, ok := fType.Tag.Lookup("from"); ok {
-			key = from
+		respBytes, err := r.client.requestContext(ctx, "POST", r.name, nil, body)
+		if err != nil {
+			return nil, err
 		}
 
-		v := m[key]
-		switch f.Kind() {
-		case reflect.Struct:
-			handleStruct(key, &f, &v)
-		case reflect.Bool:
-			handleBool(key, &f, &v)
-		case reflect.Int:
-			handleInt(key, &f, &v)
-		case reflect.Float64:
-			handleFloat(key, &f, &v)
-		case reflect.String:
-			handleString(key, &f, &v)
-		case reflect.Slice:
-			handleSlice(key, &f, &v)
-		default:
-			panic(fmt.Sprintf("UNHANDLED CASE: %s of kind %s", key, f.Kind()))
+		var resp listResponse
+		if err := json.Unmarshal(respBytes, &resp); err != nil {
+			return nil, err
 		}
+		out = append(out, resp.Records...)
 	}
+	return out, nil
 }
-func handleBool(key string, f *reflect.Value, v *interface{}) {
-	b, ok := (*v).(bool)
-	if !ok {
-		panic(fmt.Sprintf("PARSE ERROR: could not parse column '%s' as bool", key))
+
+// Update updates the record identified by id from record. When patch
+// is true, the request is a PATCH: fields holding their Go zero value
+// are treated as unset and left out of the request, so only the
+// non-zero fields in record are changed. Otherwise it's a PUT, every
+// field is sent as-is, and any field absent from record is cleared.
+func (r *Resource) Update(id string, record interface{}, patch bool) (*GetResponse, error) {
+	return r.UpdateContext(context.Background(), id, record, patch)
+}
+
+// UpdateContext is like Update but carries ctx through to the
+// underlying HTTP request.
+func (r *Resource) UpdateContext(ctx context.Context, id string, record interface{}, patch bool) (*GetResponse, error) {
+	method := "PUT"
+	if patch {
+		method = "PATCH"
+	}
+
+	body, err := json.Marshal(writeRecord{Fields: encodeFields(record, patch)})
+	if err != nil {
+		return nil, err
 	}
-	f.SetBool(b)
+
+	fullid := path.Join(r.name, id)
+	respBytes, err := r.client.requestContext(ctx, method, fullid, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetResponse
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
-func handleInterface(key string, f *reflect.Value, v *interface{}) {
-	f.Set(reflect.ValueOf(*v))
+// deleteResponse is what Airtable returns from a successful delete.
+type deleteResponse struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
 }
 
-// Resource ...
-type Resource struct {
-	name   string
-	client *Client
-	record interface{}
+// Delete deletes the record identified by id.
+func (r *Resource) Delete(id string) error {
+	return r.DeleteContext(context.Background(), id)
 }
 
-// NewResource returns a new resource manipulator
-func (c *Client) NewResource(name string, record interface{}) Resource {
-	// TODO: panic early if record is not a pointer
-	return Resource{name, c, record}
+// DeleteContext is like Delete but carries ctx through to the
+// underlying HTTP request.
+func (r *Resource) DeleteContext(ctx context.Context, id string) error {
+	fullid := path.Join(r.name, id)
+	respBytes, err := r.client.requestContext(ctx, "DELETE", fullid, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var resp deleteResponse
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return err
+	}
+	if !resp.Deleted {
+		return ErrClientRequestError{fmt.Sprintf("airtable: record %s was not deleted", id)}
+	}
+	return nil
 }
 
 // RequestBytes makes a raw request to the Airtable API
 func (c *Client) RequestBytes(method string, endpoint string, options QueryEncoder) ([]byte, error) {
-	var err error
+	return c.RequestBytesContext(context.Background(), method, endpoint, options)
+}
 
+// RequestBytesContext is like RequestBytes but carries ctx through to
+// the underlying HTTP request and the rate limiter, so a caller can
+// cancel a slow request or bound it with a deadline instead of being
+// stuck with whatever HTTPClient.Timeout applies to every request.
+func (c *Client) RequestBytesContext(ctx context.Context, method string, endpoint string, options QueryEncoder) ([]byte, error) {
+	return c.requestContext(ctx, method, endpoint, options, nil)
+}
+
+// requestContext is the shared core behind RequestBytesContext and the
+// record-writing methods on Resource, which need to send a JSON body
+// alongside method and options. It automatically retries 429 and 5xx
+// responses, honoring Retry-After when Airtable sends one and falling
+// back to exponential backoff with jitter otherwise.
+func (c *Client) requestContext(ctx context.Context, method string, endpoint string, options QueryEncoder, body []byte) ([]byte, error) {
 	// panic if the client isn't setup correctly to make a request
 	c.checkSetup()
 
@@ -285,29 +531,79 @@ func (c *Client) RequestBytes(method string, endpoint string, options QueryEncod
 
 	url := c.makeURL(endpoint, options)
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
-	if err != nil {
-		return nil, err
-	}
-	req.Header = make(http.Header)
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	for attempt := 0; ; attempt++ {
+		var bodyReader io.Reader = http.NoBody
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
 
-	if os.Getenv("AIRTABLE_NO_LIMIT") == "" {
-		limiter.Take()
-	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
+		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header = make(http.Header)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+		if body != nil {
+			req.Header.Add("Content-Type", "application/json")
+		}
+		if c.UserAgent != "" {
+			req.Header.Add("User-Agent", c.UserAgent)
+		}
 
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+		if !c.noLimit() {
+			if err := takeContext(ctx, c.Limiter); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		respBytes, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if isRetryableStatus(resp.StatusCode) && attempt < c.MaxRetries {
+			select {
+			case <-time.After(retryDelay(resp, attempt)):
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		if err = checkErrorResponse(respBytes); err != nil {
+			return respBytes, err
+		}
+
+		return respBytes, nil
 	}
+}
 
-	if err = checkErrorResponse(bytes); err != nil {
-		return bytes, err
+// isRetryableStatus reports whether status is worth retrying: Airtable
+// asking us to slow down, or a transient server-side failure.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && status < 600)
+}
+
+// retryDelay honors the Retry-After header when Airtable sends one
+// (as either a delay in seconds or an HTTP date), and otherwise falls
+// back to exponential backoff with jitter.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(ra); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+		}
 	}
 
-	return bytes, nil
+	base := 100 * time.Millisecond * (1 << uint(attempt))
+	return base + time.Duration(rand.Int63n(int64(base)))
 }
@@ -0,0 +1,102 @@
+package airtable
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient("key", "base")
+	if c.HTTPClient != http.DefaultClient {
+		t.Fatalf("expected default HTTPClient, got %v", c.HTTPClient)
+	}
+	if c.MaxRetries != unsetMaxRetries {
+		t.Fatalf("expected MaxRetries to start unset, got %d", c.MaxRetries)
+	}
+
+	c.checkSetup()
+	if c.Version != defaultVersion {
+		t.Fatalf("expected default version %q, got %q", defaultVersion, c.Version)
+	}
+	if c.RootURL != defaultRootURL {
+		t.Fatalf("expected default root URL %q, got %q", defaultRootURL, c.RootURL)
+	}
+	if c.RateLimit != defaultRateLimit {
+		t.Fatalf("expected default rate limit %d, got %d", defaultRateLimit, c.RateLimit)
+	}
+	if c.MaxRetries != defaultMaxRetries {
+		t.Fatalf("expected default max retries %d, got %d", defaultMaxRetries, c.MaxRetries)
+	}
+}
+
+func TestNewClientAppliesOptions(t *testing.T) {
+	hc := &http.Client{}
+	c := NewClient("key", "base",
+		WithHTTPClient(hc),
+		WithVersion("v1"),
+		WithRootURL("https://example.test"),
+		WithRateLimit(10),
+		WithUserAgent("my-agent"),
+		WithNoLimit(),
+	)
+
+	if c.HTTPClient != hc {
+		t.Fatal("expected WithHTTPClient to take effect")
+	}
+	if c.Version != "v1" {
+		t.Fatalf("expected version v1, got %q", c.Version)
+	}
+	if c.RootURL != "https://example.test" {
+		t.Fatalf("expected overridden root URL, got %q", c.RootURL)
+	}
+	if c.RateLimit != 10 {
+		t.Fatalf("expected rate limit 10, got %d", c.RateLimit)
+	}
+	if c.UserAgent != "my-agent" {
+		t.Fatalf("expected user agent my-agent, got %q", c.UserAgent)
+	}
+	if !c.NoLimit {
+		t.Fatal("expected WithNoLimit to set NoLimit")
+	}
+}
+
+func TestQueryEncode(t *testing.T) {
+	q := NewQuery().
+		Fields([]string{"Name", "Qty"}).
+		FilterByFormula("{Qty} > 0").
+		Sort([]SortSpec{{Field: "Name", Direction: "asc"}}).
+		View("Grid view").
+		MaxRecords(50).
+		PageSize(25).
+		Offset("cursor1").
+		CellFormat("string").
+		TimeZone("UTC").
+		UserLocale("en-US")
+
+	parsed, err := url.ParseQuery(q.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error parsing encoded query: %s", err)
+	}
+
+	cases := map[string]string{
+		"filterByFormula":    "{Qty} > 0",
+		"sort[0][field]":     "Name",
+		"sort[0][direction]": "asc",
+		"view":               "Grid view",
+		"maxRecords":         "50",
+		"pageSize":           "25",
+		"offset":             "cursor1",
+		"cellFormat":         "string",
+		"timeZone":           "UTC",
+		"userLocale":         "en-US",
+	}
+	for key, want := range cases {
+		if got := parsed.Get(key); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+	if got := parsed["fields[]"]; len(got) != 2 || got[0] != "Name" || got[1] != "Qty" {
+		t.Errorf("expected fields[]=[Name Qty], got %v", got)
+	}
+}
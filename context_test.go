@@ -0,0 +1,46 @@
+package airtable
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// blockingLimiter never grants a slot; it exists to prove takeContext
+// gives up as soon as ctx is done instead of waiting on the limiter.
+type blockingLimiter struct{}
+
+func (blockingLimiter) Take() time.Time {
+	select {}
+}
+
+func TestTakeContextRespectsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := takeContext(ctx, blockingLimiter{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRequestBytesContextDeadlineExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", "base", WithRootURL(srv.URL), WithNoLimit())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	_, err := c.RequestBytesContext(ctx, "GET", "Main", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
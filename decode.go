@@ -0,0 +1,206 @@
+package airtable
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ErrFieldDecode is returned when an Airtable field's value can't be
+// decoded into the struct field it's destined for.
+type ErrFieldDecode struct {
+	Field string
+	Kind  reflect.Kind
+	Cause error
+}
+
+func (e ErrFieldDecode) Error() string {
+	return fmt.Sprintf("airtable: could not decode field %q into %s: %s", e.Field, e.Kind, e.Cause)
+}
+
+// Unwrap lets errors.Is/As see through to Cause.
+func (e ErrFieldDecode) Unwrap() error {
+	return e.Cause
+}
+
+// FieldDecoder decodes a single Airtable field value into dest, a
+// settable reflect.Value of the field's Go type. Register one with
+// Client.RegisterDecoder to teach this package about a field type it
+// doesn't natively understand (Barcode, Button, Duration,
+// Collaborator, Lookup arrays, ...).
+type FieldDecoder interface {
+	DecodeField(dest reflect.Value, raw interface{}) error
+}
+
+// FieldDecoderFunc adapts a function to a FieldDecoder.
+type FieldDecoderFunc func(dest reflect.Value, raw interface{}) error
+
+// DecodeField implements FieldDecoder.
+func (f FieldDecoderFunc) DecodeField(dest reflect.Value, raw interface{}) error {
+	return f(dest, raw)
+}
+
+// defaultDecoders holds the decoders for this package's built-in
+// field types (see types.go). A Client's own decoders, installed with
+// RegisterDecoder, take priority over these.
+var defaultDecoders = map[reflect.Type]FieldDecoder{}
+
+func registerDefaultDecoder(t reflect.Type, dec FieldDecoder) {
+	defaultDecoders[t] = dec
+}
+
+// RegisterDecoder installs dec as the decoder for fields of type t,
+// overriding any built-in decoder registered for that type.
+func (c *Client) RegisterDecoder(t reflect.Type, dec FieldDecoder) {
+	if c.decoders == nil {
+		c.decoders = make(map[reflect.Type]FieldDecoder)
+	}
+	c.decoders[t] = dec
+}
+
+// lookupDecoder finds the decoder for t, preferring c's own decoders
+// over the package's built-ins. c may be nil.
+func (c *Client) lookupDecoder(t reflect.Type) FieldDecoder {
+	if c != nil {
+		if dec, ok := c.decoders[t]; ok {
+			return dec
+		}
+	}
+	return defaultDecoders[t]
+}
+
+// decodeFields copies the values in fields into dest, a pointer to a
+// struct, matching struct fields to Airtable field names either by
+// field name or by a `from:"..."` tag. c may be nil, in which case
+// only built-in decoders are consulted.
+func decodeFields(c *Client, fields map[string]interface{}, dest interface{}) error {
+	refStruct := reflect.ValueOf(dest).Elem()
+	refStructType := refStruct.Type()
+
+	for i := 0; i < refStruct.NumField(); i++ {
+		f := refStruct.Field(i)
+		fType := refStructType.Field(i)
+
+		key := fType.Name
+		if from, ok := fType.Tag.Lookup("from"); ok {
+			key = from
+		}
+
+		v, ok := fields[key]
+		if !ok || v == nil {
+			continue
+		}
+		if err := decodeValue(c, key, f, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes raw into dest, trying a registered FieldDecoder
+// first and falling back to the built-in handling for basic Go kinds.
+func decodeValue(c *Client, key string, dest reflect.Value, raw interface{}) error {
+	if dec := c.lookupDecoder(dest.Type()); dec != nil {
+		if err := dec.DecodeField(dest, raw); err != nil {
+			return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: err}
+		}
+		return nil
+	}
+
+	switch dest.Kind() {
+	case reflect.Struct:
+		return decodeStruct(c, key, dest, raw)
+	case reflect.Bool:
+		return decodeBool(key, dest, raw)
+	case reflect.Int:
+		return decodeInt(key, dest, raw)
+	case reflect.Float64:
+		return decodeFloat(key, dest, raw)
+	case reflect.String:
+		return decodeString(key, dest, raw)
+	case reflect.Slice:
+		return decodeSlice(c, key, dest, raw)
+	case reflect.Interface:
+		dest.Set(reflect.ValueOf(raw))
+		return nil
+	default:
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("unhandled kind %s", dest.Kind())}
+	}
+}
+
+func decodeString(key string, dest reflect.Value, raw interface{}) error {
+	str, ok := raw.(string)
+	if !ok {
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("expected string, got %T", raw)}
+	}
+	dest.SetString(str)
+	return nil
+}
+
+func decodeInt(key string, dest reflect.Value, raw interface{}) error {
+	// JavaScript/JSON doesn't have ints, only float64s
+	n, ok := raw.(float64)
+	if !ok {
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("expected number, got %T", raw)}
+	}
+	dest.SetInt(int64(n))
+	return nil
+}
+
+func decodeFloat(key string, dest reflect.Value, raw interface{}) error {
+	n, ok := raw.(float64)
+	if !ok {
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("expected number, got %T", raw)}
+	}
+	dest.SetFloat(n)
+	return nil
+}
+
+func decodeBool(key string, dest reflect.Value, raw interface{}) error {
+	b, ok := raw.(bool)
+	if !ok {
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("expected bool, got %T", raw)}
+	}
+	dest.SetBool(b)
+	return nil
+}
+
+func decodeSlice(c *Client, key string, dest reflect.Value, raw interface{}) error {
+	s, ok := raw.([]interface{})
+	if !ok {
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("expected array, got %T", raw)}
+	}
+
+	result := reflect.MakeSlice(dest.Type(), len(s), len(s))
+	for i, v := range s {
+		if err := decodeValue(c, key, result.Index(i), v); err != nil {
+			return err
+		}
+	}
+	dest.Set(result)
+	return nil
+}
+
+func decodeStruct(c *Client, key string, dest reflect.Value, raw interface{}) error {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return ErrFieldDecode{Field: key, Kind: dest.Kind(), Cause: fmt.Errorf("expected object, got %T", raw)}
+	}
+
+	structType := dest.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fType := structType.Field(i)
+		fieldKey := fType.Name
+		if from, ok := fType.Tag.Lookup("from"); ok {
+			fieldKey = from
+		}
+
+		v, ok := m[fieldKey]
+		if !ok || v == nil {
+			continue
+		}
+		if err := decodeValue(c, fieldKey, dest.Field(i), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
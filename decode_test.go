@@ -0,0 +1,50 @@
+package airtable
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeFieldsUnknownKindReturnsError(t *testing.T) {
+	type record struct {
+		Conn chan int
+	}
+
+	var r record
+	err := decodeFields(nil, map[string]interface{}{"Conn": "nope"}, &r)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	var fieldErr ErrFieldDecode
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("expected ErrFieldDecode, got %T: %s", err, err)
+	}
+	if fieldErr.Field != "Conn" {
+		t.Fatalf("expected field %q, got %q", "Conn", fieldErr.Field)
+	}
+}
+
+func TestRegisterDecoderOverridesBuiltin(t *testing.T) {
+	type record struct {
+		Link RecordLink
+	}
+
+	c := &Client{}
+	c.RegisterDecoder(reflect.TypeOf(RecordLink{}), FieldDecoderFunc(
+		func(dest reflect.Value, raw interface{}) error {
+			dest.Set(reflect.ValueOf(RecordLink{"overridden"}))
+			return nil
+		},
+	))
+
+	var r record
+	if err := decodeFields(c, map[string]interface{}{"Link": []interface{}{"rec123"}}, &r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(r.Link) != 1 || r.Link[0] != "overridden" {
+		t.Fatalf("expected overridden decoder to run, got %#v", r.Link)
+	}
+}
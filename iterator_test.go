@@ -0,0 +1,140 @@
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRecordIteratorFollowsOffsetAcrossPages(t *testing.T) {
+	pages := []listResponse{
+		{
+			Records: []GetResponse{
+				{ID: "rec1", Fields: map[string]interface{}{"Name": "bolt"}},
+				{ID: "rec2", Fields: map[string]interface{}{"Name": "nut"}},
+			},
+			Offset: "cursor1",
+		},
+		{
+			Records: []GetResponse{
+				{ID: "rec3", Fields: map[string]interface{}{"Name": "screw"}},
+			},
+		},
+	}
+
+	var requestCount int
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		offset := r.URL.Query().Get("offset")
+		var page listResponse
+		if offset == "" {
+			page = pages[0]
+		} else if offset == "cursor1" {
+			page = pages[1]
+		} else {
+			t.Fatalf("unexpected offset %q", offset)
+		}
+		requestCount++
+		json.NewEncoder(w).Encode(page)
+	})
+	defer closeSrv()
+
+	table := c.Table("Widgets")
+	it := table.Iterate(context.Background(), nil)
+	var ids []string
+	for it.Next() {
+		var w widget
+		if err := it.Record(&w); err != nil {
+			t.Fatalf("unexpected decode error: %s", err)
+		}
+		ids = append(ids, it.current.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected iterator error: %s", err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected 3 records across pages, got %d", len(ids))
+	}
+	if requestCount != 2 {
+		t.Fatalf("expected 2 page requests, got %d", requestCount)
+	}
+}
+
+func TestRecordIteratorStopsAtMaxRecords(t *testing.T) {
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(listResponse{
+			Records: []GetResponse{
+				{ID: "rec1", Fields: map[string]interface{}{}},
+				{ID: "rec2", Fields: map[string]interface{}{}},
+				{ID: "rec3", Fields: map[string]interface{}{}},
+			},
+		})
+	})
+	defer closeSrv()
+
+	table := c.Table("Widgets")
+	it := table.Iterate(context.Background(), NewQuery().MaxRecords(2))
+
+	var count int
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected iterator error: %s", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected MaxRecords to cap at 2, got %d", count)
+	}
+}
+
+func TestTableListDecodesAllPages(t *testing.T) {
+	var requestCount int
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		offset := r.URL.Query().Get("offset")
+		if offset == "" {
+			json.NewEncoder(w).Encode(listResponse{
+				Records: []GetResponse{{ID: "rec1", Fields: map[string]interface{}{"Name": "bolt", "Qty": float64(3)}}},
+				Offset:  "cursor1",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(listResponse{
+			Records: []GetResponse{{ID: "rec2", Fields: map[string]interface{}{"Name": "nut", "Qty": float64(5)}}},
+		})
+	})
+	defer closeSrv()
+
+	table := c.Table("Widgets")
+	var out []widget
+	if err := table.List(&out, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(out))
+	}
+	if out[0].Name != "bolt" || out[1].Name != "nut" {
+		t.Fatalf("unexpected decoded records: %+v", out)
+	}
+	if requestCount != 2 {
+		t.Fatalf("expected 2 page requests, got %d", requestCount)
+	}
+}
+
+func TestRecordIteratorPageQueryPreservesCallerOptions(t *testing.T) {
+	table := &Table{name: "Widgets"}
+	it := table.Iterate(context.Background(), NewQuery().View("Grid view"))
+	it.offset = "cursor1"
+
+	parsed, err := url.ParseQuery(it.pageQuery().Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if parsed.Get("view") != "Grid view" {
+		t.Fatalf("expected view to be preserved across pages, got %q", parsed.Get("view"))
+	}
+	if parsed.Get("offset") != "cursor1" {
+		t.Fatalf("expected offset to be set, got %q", parsed.Get("offset"))
+	}
+}
@@ -0,0 +1,102 @@
+package airtable
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// SortSpec is one field/direction pair in a Sort clause.
+type SortSpec struct {
+	Field     string
+	Direction string // "asc" or "desc"
+}
+
+// Query builds the query parameters accepted by Airtable's list and
+// get endpoints, so callers don't have to hand-build url.Values (and
+// misspell "fields[]" while doing it). It implements QueryEncoder, so
+// it can be passed anywhere a QueryEncoder is expected.
+type Query struct {
+	values url.Values
+}
+
+// NewQuery returns an empty Query ready to be built up with its
+// fluent methods.
+func NewQuery() *Query {
+	return &Query{values: make(url.Values)}
+}
+
+// Fields restricts the response to the named fields.
+func (q *Query) Fields(fields []string) *Query {
+	for _, f := range fields {
+		q.values.Add("fields[]", f)
+	}
+	return q
+}
+
+// FilterByFormula filters records using an Airtable formula.
+func (q *Query) FilterByFormula(formula string) *Query {
+	q.values.Set("filterByFormula", formula)
+	return q
+}
+
+// Sort orders records by the given fields, applied in order.
+func (q *Query) Sort(specs []SortSpec) *Query {
+	for i, s := range specs {
+		q.values.Set(fmt.Sprintf("sort[%d][field]", i), s.Field)
+		q.values.Set(fmt.Sprintf("sort[%d][direction]", i), s.Direction)
+	}
+	return q
+}
+
+// View restricts the response to records visible in the named view.
+func (q *Query) View(view string) *Query {
+	q.values.Set("view", view)
+	return q
+}
+
+// MaxRecords caps the total number of records returned across all
+// pages.
+func (q *Query) MaxRecords(n int) *Query {
+	q.values.Set("maxRecords", strconv.Itoa(n))
+	return q
+}
+
+// PageSize sets how many records come back per page (Airtable's
+// maximum, and default, is 100).
+func (q *Query) PageSize(n int) *Query {
+	q.values.Set("pageSize", strconv.Itoa(n))
+	return q
+}
+
+// Offset sets the pagination cursor returned by a previous page.
+func (q *Query) Offset(offset string) *Query {
+	q.values.Set("offset", offset)
+	return q
+}
+
+// CellFormat selects how cell values are formatted ("json" or
+// "string").
+func (q *Query) CellFormat(format string) *Query {
+	q.values.Set("cellFormat", format)
+	return q
+}
+
+// TimeZone sets the time zone used to format dates when CellFormat is
+// "string".
+func (q *Query) TimeZone(tz string) *Query {
+	q.values.Set("timeZone", tz)
+	return q
+}
+
+// UserLocale sets the locale used to format dates when CellFormat is
+// "string".
+func (q *Query) UserLocale(locale string) *Query {
+	q.values.Set("userLocale", locale)
+	return q
+}
+
+// Encode implements QueryEncoder.
+func (q *Query) Encode() string {
+	return q.values.Encode()
+}
@@ -0,0 +1,203 @@
+package airtable
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type widget struct {
+	Name string `from:"Name"`
+	Qty  int    `from:"Qty"`
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, func()) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	c := NewClient("key", "base", WithRootURL(srv.URL), WithNoLimit())
+	return c, srv.Close
+}
+
+func TestResourceCreateSendsFields(t *testing.T) {
+	var gotBody batchPayload
+
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatalf("decoding request body: %s", err)
+		}
+		json.NewEncoder(w).Encode(listResponse{
+			Records: []GetResponse{{ID: "rec1", Fields: map[string]interface{}{"Name": "bolt", "Qty": float64(3)}}},
+		})
+	})
+	defer closeSrv()
+
+	r := c.NewResource("Widgets", &widget{})
+	got, err := r.Create(&widget{Name: "bolt", Qty: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.ID != "rec1" {
+		t.Fatalf("expected id rec1, got %q", got.ID)
+	}
+	if len(gotBody.Records) != 1 {
+		t.Fatalf("expected 1 record in request, got %d", len(gotBody.Records))
+	}
+	fields, ok := gotBody.Records[0].Fields.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected fields to decode as a map, got %T", gotBody.Records[0].Fields)
+	}
+	if fields["Name"] != "bolt" || fields["Qty"] != float64(3) {
+		t.Fatalf("expected Name=bolt and Qty=3 to be sent, got %v", fields)
+	}
+}
+
+func TestResourceCreateContextEmptyResponseIsError(t *testing.T) {
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(listResponse{})
+	})
+	defer closeSrv()
+
+	r := c.NewResource("Widgets", &widget{})
+	_, err := r.Create(&widget{Name: "bolt"})
+	if err == nil {
+		t.Fatal("expected an error for an empty create response, got nil")
+	}
+}
+
+func TestResourceCreateBatchChunksAt10(t *testing.T) {
+	var requestCount int
+
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		var payload batchPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		recs := make([]GetResponse, len(payload.Records))
+		for i := range payload.Records {
+			recs[i] = GetResponse{ID: "rec"}
+		}
+		json.NewEncoder(w).Encode(listResponse{Records: recs})
+	})
+	defer closeSrv()
+
+	records := make([]interface{}, 25)
+	for i := range records {
+		records[i] = &widget{Name: "bolt"}
+	}
+
+	r := c.NewResource("Widgets", &widget{})
+	out, err := r.CreateBatch(records, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if requestCount != 3 {
+		t.Fatalf("expected 3 chunked requests for 25 records, got %d", requestCount)
+	}
+	if len(out) != 25 {
+		t.Fatalf("expected 25 records back, got %d", len(out))
+	}
+}
+
+func TestResourceUpdatePatchOmitsZeroFields(t *testing.T) {
+	var gotBody writeRecord
+
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("expected PATCH, got %s", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		json.NewEncoder(w).Encode(GetResponse{ID: "rec1"})
+	})
+	defer closeSrv()
+
+	r := c.NewResource("Widgets", &widget{})
+	if _, err := r.Update("rec1", &widget{Name: "bolt"}, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fields, ok := gotBody.Fields.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected fields to decode as a map, got %T", gotBody.Fields)
+	}
+	if _, present := fields["Qty"]; present {
+		t.Fatalf("expected zero-valued Qty to be omitted from a PATCH, got %v", fields)
+	}
+	if fields["Name"] != "bolt" {
+		t.Fatalf("expected Name to be sent, got %v", fields)
+	}
+}
+
+func TestResourceUpdatePutSendsZeroFields(t *testing.T) {
+	var gotBody writeRecord
+
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			t.Errorf("expected PUT, got %s", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		json.NewEncoder(w).Encode(GetResponse{ID: "rec1"})
+	})
+	defer closeSrv()
+
+	r := c.NewResource("Widgets", &widget{})
+	if _, err := r.Update("rec1", &widget{Name: "bolt"}, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fields, ok := gotBody.Fields.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected fields to decode as a map, got %T", gotBody.Fields)
+	}
+	if _, present := fields["Qty"]; !present {
+		t.Fatalf("expected a PUT to send the zero-valued Qty, got %v", fields)
+	}
+}
+
+func TestResourceDelete(t *testing.T) {
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("expected DELETE, got %s", r.Method)
+		}
+		json.NewEncoder(w).Encode(deleteResponse{ID: "rec1", Deleted: true})
+	})
+	defer closeSrv()
+
+	r := c.NewResource("Widgets", &widget{})
+	if err := r.Delete("rec1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestResourceDeleteNotDeletedIsError(t *testing.T) {
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(deleteResponse{ID: "rec1", Deleted: false})
+	})
+	defer closeSrv()
+
+	r := c.NewResource("Widgets", &widget{})
+	if err := r.Delete("rec1"); err == nil {
+		t.Fatal("expected an error when Airtable reports deleted=false, got nil")
+	}
+}
+
+func TestCheckErrorResponseBatchPartialFailure(t *testing.T) {
+	body := []byte(`{"records":[{"id":"rec1","fields":{}},{"error":{"type":"INVALID_VALUE","message":"bad value"}}]}`)
+
+	err := checkErrorResponse(body)
+	var batchErr ErrBatchRequestError
+	if err == nil {
+		t.Fatal("expected an error for a partially-failed batch, got nil")
+	}
+	if be, ok := err.(ErrBatchRequestError); ok {
+		batchErr = be
+	} else {
+		t.Fatalf("expected ErrBatchRequestError, got %T", err)
+	}
+	if len(batchErr.Errors) != 1 {
+		t.Fatalf("expected 1 per-record error, got %d", len(batchErr.Errors))
+	}
+}
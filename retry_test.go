@@ -0,0 +1,102 @@
+package airtable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestContextRetriesOnRetryAfterSeconds(t *testing.T) {
+	var attempts int
+
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	defer closeSrv()
+
+	if _, err := c.RequestBytes("GET", "Widgets", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRequestContextRetriesOnRetryAfterHTTPDate(t *testing.T) {
+	var attempts int
+
+	c, closeSrv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", time.Now().Add(10*time.Millisecond).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	defer closeSrv()
+
+	if _, err := c.RequestBytes("GET", "Widgets", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRequestContextGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", "base", WithRootURL(srv.URL), WithNoLimit(), WithRetry(2))
+	if _, err := c.RequestBytes("GET", "Widgets", nil); err == nil {
+		t.Fatal("expected an error once retries are exhausted, got nil")
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 1 initial attempt + 2 retries = 3, got %d", attempts)
+	}
+}
+
+func TestWithRetryZeroSticks(t *testing.T) {
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", "base", WithRootURL(srv.URL), WithNoLimit(), WithRetry(0))
+	if _, err := c.RequestBytes("GET", "Widgets", nil); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected WithRetry(0) to disable retries entirely (1 attempt), got %d", attempts)
+	}
+}
+
+func TestRetryDelayFallsBackToExponentialBackoff(t *testing.T) {
+	resp := &http.Response{Header: make(http.Header)}
+
+	d0 := retryDelay(resp, 0)
+	d1 := retryDelay(resp, 1)
+
+	if d0 < 100*time.Millisecond || d0 >= 200*time.Millisecond {
+		t.Fatalf("expected attempt 0 delay in [100ms, 200ms), got %s", d0)
+	}
+	if d1 < 200*time.Millisecond || d1 >= 400*time.Millisecond {
+		t.Fatalf("expected attempt 1 delay in [200ms, 400ms), got %s", d1)
+	}
+}
@@ -0,0 +1,196 @@
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+)
+
+// Table is a handle to a single table within a base. Unlike Resource,
+// which decodes into the record type it was constructed with, Table
+// decodes into whatever destination the caller passes to Get or List,
+// so one Table can be reused across record types.
+type Table struct {
+	name   string
+	client *Client
+}
+
+// Table returns a handle to the named table in the client's base.
+func (c *Client) Table(name string) *Table {
+	return &Table{name: name, client: c}
+}
+
+// listResponse mirrors the envelope Airtable wraps list results in.
+type listResponse struct {
+	Records []GetResponse `json:"records"`
+	Offset  string        `json:"offset"`
+}
+
+// Get fetches a single record by id and decodes its fields into out,
+// which must be a pointer to a struct.
+func (t *Table) Get(id string, out interface{}) error {
+	return t.GetContext(context.Background(), id, out)
+}
+
+// GetContext is like Get but carries ctx through to the underlying
+// HTTP request.
+func (t *Table) GetContext(ctx context.Context, id string, out interface{}) error {
+	fullid := path.Join(t.name, id)
+	bytes, err := t.client.RequestBytesContext(ctx, "GET", fullid, nil)
+	if err != nil {
+		return err
+	}
+
+	var resp GetResponse
+	if err := json.Unmarshal(bytes, &resp); err != nil {
+		return err
+	}
+
+	return decodeFields(t.client, resp.Fields, out)
+}
+
+// List fetches every record matching options, transparently following
+// Airtable's offset cursor across pages (stopping early once
+// options.MaxRecords is satisfied, if set), and decodes them into out,
+// which must be a pointer to a slice of structs.
+func (t *Table) List(out interface{}, options QueryEncoder) error {
+	return t.ListContext(context.Background(), out, options)
+}
+
+// ListContext is like List but carries ctx through to the underlying
+// HTTP requests.
+func (t *Table) ListContext(ctx context.Context, out interface{}, options QueryEncoder) error {
+	sliceVal := reflect.ValueOf(out).Elem()
+	elemType := sliceVal.Type().Elem()
+	result := reflect.MakeSlice(sliceVal.Type(), 0, 0)
+
+	it := t.Iterate(ctx, options)
+	for it.Next() {
+		elem := reflect.New(elemType)
+		if err := it.Record(elem.Interface()); err != nil {
+			return err
+		}
+		result = reflect.Append(result, elem.Elem())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	sliceVal.Set(result)
+	return nil
+}
+
+// RecordIterator iterates over a table's records, fetching additional
+// pages transparently as Next is called so callers processing large
+// bases don't have to hold every record in memory at once.
+type RecordIterator struct {
+	ctx   context.Context
+	table *Table
+	base  QueryEncoder
+
+	maxRecords  int
+	fetched     int
+	page        []GetResponse
+	pageIdx     int
+	offset      string
+	noMorePages bool
+
+	current GetResponse
+	err     error
+}
+
+// Iterate returns a RecordIterator over the table's records matching
+// query, fetching pages lazily as Next is called. Passing nil iterates
+// every record in the table.
+func (t *Table) Iterate(ctx context.Context, query QueryEncoder) *RecordIterator {
+	it := &RecordIterator{ctx: ctx, table: t, base: query}
+	if query != nil {
+		if parsed, err := url.ParseQuery(query.Encode()); err == nil {
+			if n, err := strconv.Atoi(parsed.Get("maxRecords")); err == nil {
+				it.maxRecords = n
+			}
+		}
+	}
+	return it
+}
+
+// Next advances the iterator to the next record and reports whether
+// one is available. It returns false at the end of the table or on
+// error; call Err to tell the two apart.
+func (it *RecordIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.maxRecords > 0 && it.fetched >= it.maxRecords {
+		return false
+	}
+	if it.pageIdx >= len(it.page) {
+		if it.noMorePages {
+			return false
+		}
+		if err := it.fetchPage(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.current = it.page[it.pageIdx]
+	it.pageIdx++
+	it.fetched++
+	return true
+}
+
+// Record decodes the current record's fields into out, which must be
+// a pointer to a struct.
+func (it *RecordIterator) Record(out interface{}) error {
+	return decodeFields(it.table.client, it.current.Fields, out)
+}
+
+// Err returns the first error encountered while fetching pages, if
+// any.
+func (it *RecordIterator) Err() error {
+	return it.err
+}
+
+// fetchPage requests the next page of records, reusing the caller's
+// original query options with the offset cursor swapped in.
+func (it *RecordIterator) fetchPage() error {
+	respBytes, err := it.table.client.requestContext(it.ctx, "GET", it.table.name, it.pageQuery(), nil)
+	if err != nil {
+		return err
+	}
+
+	var resp listResponse
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return err
+	}
+
+	it.page = resp.Records
+	it.pageIdx = 0
+	it.offset = resp.Offset
+	if it.offset == "" {
+		it.noMorePages = true
+	}
+	return nil
+}
+
+// pageQuery returns the query for the next page: the caller's
+// original options with offset set (or unset, for the first page).
+func (it *RecordIterator) pageQuery() QueryEncoder {
+	values := url.Values{}
+	if it.base != nil {
+		if parsed, err := url.ParseQuery(it.base.Encode()); err == nil {
+			values = parsed
+		}
+	}
+	if it.offset != "" {
+		values.Set("offset", it.offset)
+	}
+	return values
+}
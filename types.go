@@ -0,0 +1,127 @@
+package airtable
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Text, LongText, Checkbox, Rating and MultipleSelect are plain
+// Airtable field types that decode through the ordinary reflection
+// path; they exist mainly to give a field a name to hang a
+// `from:"..."` tag on.
+type (
+	Text           string
+	LongText       string
+	Checkbox       bool
+	Rating         int
+	MultipleSelect []string
+)
+
+// Date represents an Airtable date or date-time field.
+type Date struct {
+	time.Time
+}
+
+// AttachmentFile is a single file within an Attachment field.
+type AttachmentFile struct {
+	ID         string                         `json:"id"`
+	URL        string                         `json:"url"`
+	Filename   string                         `json:"filename"`
+	Size       int                            `json:"size"`
+	Type       string                         `json:"type"`
+	Thumbnails map[string]AttachmentThumbnail `json:"thumbnails"`
+}
+
+// AttachmentThumbnail is one rendered size of an AttachmentFile.
+type AttachmentThumbnail struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Attachment represents an Airtable attachment field, which Airtable
+// sends as an array of files even when there's only one.
+type Attachment []AttachmentFile
+
+// RecordLink represents an Airtable "link to another record" field:
+// the list of linked record IDs.
+type RecordLink []string
+
+// FormulaResult holds the value of an Airtable formula field. Its
+// underlying type depends on the formula: string, float64, bool, or a
+// slice of those.
+type FormulaResult struct {
+	Value interface{}
+}
+
+func init() {
+	registerDefaultDecoder(reflect.TypeOf(Date{}), FieldDecoderFunc(decodeDate))
+	registerDefaultDecoder(reflect.TypeOf(Attachment{}), FieldDecoderFunc(decodeAttachment))
+	registerDefaultDecoder(reflect.TypeOf(RecordLink{}), FieldDecoderFunc(decodeRecordLink))
+	registerDefaultDecoder(reflect.TypeOf(FormulaResult{}), FieldDecoderFunc(decodeFormulaResult))
+}
+
+// dateLayouts are the formats Airtable sends depending on whether the
+// field includes a time component.
+var dateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func decodeDate(dest reflect.Value, raw interface{}) error {
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Errorf("expected string, got %T", raw)
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	for _, layout := range dateLayouts {
+		t, err = time.Parse(layout, s)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	dest.Set(reflect.ValueOf(Date{t}))
+	return nil
+}
+
+// jsonRoundTrip re-marshals raw (already JSON-decoded into Go's
+// generic interface{} representation) and unmarshals it into out,
+// letting struct/json tags do the field matching instead of writing
+// it by hand.
+func jsonRoundTrip(raw interface{}, out interface{}) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func decodeAttachment(dest reflect.Value, raw interface{}) error {
+	var files []AttachmentFile
+	if err := jsonRoundTrip(raw, &files); err != nil {
+		return err
+	}
+	dest.Set(reflect.ValueOf(Attachment(files)))
+	return nil
+}
+
+func decodeRecordLink(dest reflect.Value, raw interface{}) error {
+	var ids []string
+	if err := jsonRoundTrip(raw, &ids); err != nil {
+		return err
+	}
+	dest.Set(reflect.ValueOf(RecordLink(ids)))
+	return nil
+}
+
+func decodeFormulaResult(dest reflect.Value, raw interface{}) error {
+	dest.Set(reflect.ValueOf(FormulaResult{Value: raw}))
+	return nil
+}